@@ -0,0 +1,141 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/ahmetb/kubectx/internal/kubeconfig"
+	"github.com/ahmetb/kubectx/internal/printer"
+)
+
+// FanOutOp indicates intention to run a kubectl command against every
+// context whose name matches Pattern, without changing current-context.
+type FanOutOp struct {
+	Pattern     string   // glob pattern matched against context names
+	KubectlArgs []string // arguments passed through to kubectl
+}
+
+// parseFanOutArgs parses the argument list following "--each"/"-e", which is
+// expected to look like: PATTERN -- kubectl-args...
+func parseFanOutArgs(args []string) Op {
+	if len(args) == 0 {
+		return UnsupportedOp{Err: fmt.Errorf("'--each' requires a pattern argument")}
+	}
+	pattern := args[0]
+	rest := args[1:]
+	if len(rest) == 0 || rest[0] != "--" {
+		return UnsupportedOp{Err: fmt.Errorf("'--each' requires a kubectl command, e.g. --each '%s' -- get pods", pattern)}
+	}
+	kubectlArgs := rest[1:]
+	if len(kubectlArgs) == 0 {
+		return UnsupportedOp{Err: fmt.Errorf("no kubectl command specified after '--'")}
+	}
+	return FanOutOp{Pattern: pattern, KubectlArgs: kubectlArgs}
+}
+
+func (op FanOutOp) Run(stdout, stderr io.Writer) error {
+	kc := new(kubeconfig.Kubeconfig).WithLoader(kubeconfig.DefaultLoader)
+	defer kc.Close()
+	if err := kc.Parse(); err != nil {
+		return errors.Wrap(err, "kubeconfig error")
+	}
+
+	matches, err := matchContexts(kc.ContextNames(), op.Pattern)
+	if err != nil {
+		return errors.Wrap(err, "failed to match contexts against pattern")
+	}
+	if len(matches) == 0 {
+		return errors.Errorf("no context matches pattern \"%s\"", op.Pattern)
+	}
+
+	var failed bool
+	for i, ctx := range matches {
+		prefix := printer.FanOutColor(i).Sprintf("[%s]", ctx)
+		if err := runKubectlForContext(ctx, op.KubectlArgs, prefix, stdout, stderr); err != nil {
+			fmt.Fprintf(stderr, "%s %s\n", prefix, errors.Wrap(err, "command failed"))
+			failed = true
+		}
+	}
+	if failed {
+		return errors.New("one or more contexts failed to run the command")
+	}
+	return nil
+}
+
+// runKubectlForContext runs kubectl with a --context override, so the
+// invocation is scoped to a single context without mutating current-context
+// on disk, and streams its output with a colored "[ctx-name]" prefix.
+func runKubectlForContext(ctx string, kubectlArgs []string, prefix string, stdout, stderr io.Writer) error {
+	args := append([]string{"--context", ctx}, kubectlArgs...)
+	cmd := exec.Command("kubectl", args...)
+	cmd.Env = os.Environ()
+	cmd.Stdin = os.Stdin
+
+	var wg sync.WaitGroup
+	for _, pipe := range []struct {
+		src func() (io.ReadCloser, error)
+		dst io.Writer
+	}{
+		{cmd.StdoutPipe, stdout},
+		{cmd.StderrPipe, stderr},
+	} {
+		r, err := pipe.src()
+		if err != nil {
+			return errors.Wrap(err, "failed to open command pipe")
+		}
+		wg.Add(1)
+		go func(r io.ReadCloser, dst io.Writer) {
+			defer wg.Done()
+			streamWithPrefix(prefix, r, dst)
+		}(r, pipe.dst)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "failed to start kubectl")
+	}
+	wg.Wait()
+	return cmd.Wait()
+}
+
+func streamWithPrefix(prefix string, r io.Reader, w io.Writer) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fmt.Fprintf(w, "%s %s\n", prefix, s.Text())
+	}
+}
+
+// matchContexts filters names down to those matching the given glob pattern.
+func matchContexts(names []string, pattern string) ([]string, error) {
+	var out []string
+	for _, n := range names {
+		ok, err := filepath.Match(pattern, n)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern \"%s\"", pattern)
+		}
+		if ok {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
@@ -51,15 +51,60 @@ func parseArgs(argv []string) Op {
 		return DeleteOp{Contexts: argv[1:]}
 	}
 
+	if argv[0] == "--each" || argv[0] == "-e" {
+		return parseFanOutArgs(argv[1:])
+	}
+
+	if argv[0] == "--group-add" {
+		if len(argv) < 3 {
+			return UnsupportedOp{Err: fmt.Errorf("'--group-add' requires a group name and at least one context")}
+		}
+		contexts, namespaces, err := parseGroupMembers(argv[2:])
+		if err != nil {
+			return UnsupportedOp{Err: err}
+		}
+		return GroupAddOp{Name: argv[1], Contexts: contexts, Namespaces: namespaces}
+	}
+
+	if argv[0] == "--group-rm" {
+		if len(argv) != 2 {
+			return UnsupportedOp{Err: fmt.Errorf("'--group-rm' requires exactly one group name")}
+		}
+		return GroupRemoveOp{Name: argv[1]}
+	}
+
+	if argv[0] == "--group" {
+		if len(argv) < 2 {
+			return UnsupportedOp{Err: fmt.Errorf("'--group' requires a group name argument")}
+		}
+		var namespace string
+		rest, noHooks := stripNoHooksFlag(argv[2:])
+		for i := 0; i < len(rest); i++ {
+			if rest[i] == "-n" || rest[i] == "--namespace" {
+				if i+1 >= len(rest) {
+					return UnsupportedOp{Err: fmt.Errorf("'-n' requires a namespace argument")}
+				}
+				namespace = rest[i+1]
+				break
+			}
+		}
+		return GroupSwitchOp{Name: argv[1], Namespace: namespace, NoHooks: noHooks}
+	}
+
+	// "--no-hooks" only has meaning for a context/group switch, so it's only
+	// stripped here, not from -d/--each/--group-add/--group-rm payloads
+	// where it could legitimately be a literal context or kubectl argument.
+	switchArgs, noHooks := stripNoHooksFlag(argv)
+
 	var namespace string
 	var namespaceIndex = -1
 
-	for i := 0; i < len(argv); i++ {
-		if argv[i] == "-n" || argv[i] == "--namespace" {
-			if i+1 >= len(argv) {
+	for i := 0; i < len(switchArgs); i++ {
+		if switchArgs[i] == "-n" || switchArgs[i] == "--namespace" {
+			if i+1 >= len(switchArgs) {
 				return UnsupportedOp{Err: fmt.Errorf("'-n' requires a namespace argument")}
 			}
-			namespace = argv[i+1]
+			namespace = switchArgs[i+1]
 			namespaceIndex = i
 			break
 		}
@@ -67,9 +112,9 @@ func parseArgs(argv []string) Op {
 
 	var remainingArgs []string
 	if namespaceIndex >= 0 {
-		remainingArgs = append(argv[:namespaceIndex], argv[namespaceIndex+2:]...)
+		remainingArgs = append(switchArgs[:namespaceIndex], switchArgs[namespaceIndex+2:]...)
 	} else {
-		remainingArgs = argv
+		remainingArgs = switchArgs
 	}
 
 	if len(remainingArgs) == 0 {
@@ -104,7 +149,7 @@ func parseArgs(argv []string) Op {
 		if strings.HasPrefix(v, "-") && v != "-" {
 			return UnsupportedOp{Err: fmt.Errorf("unsupported option '%s'", v)}
 		}
-		return SwitchOp{Target: v, Namespace: namespace}
+		return SwitchOp{Target: v, Namespace: namespace, NoHooks: noHooks}
 	}
 	return UnsupportedOp{Err: fmt.Errorf("too many arguments")}
 }
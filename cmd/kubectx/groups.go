@@ -0,0 +1,226 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ahmetb/kubectx/internal/groups"
+	"github.com/ahmetb/kubectx/internal/printer"
+)
+
+// GroupSwitchOp indicates intention to switch to a named group of contexts.
+// Resolution (which member context to pick) is delegated to switchContext,
+// so a group name works anywhere a context name does, including with -n.
+type GroupSwitchOp struct {
+	Name      string
+	Namespace string
+	NoHooks   bool
+}
+
+func (op GroupSwitchOp) Run(stdout, stderr io.Writer) error {
+	return SwitchOp{Target: op.Name, Namespace: op.Namespace, NoHooks: op.NoHooks}.Run(stdout, stderr)
+}
+
+// GroupAddOp indicates intention to create or replace a group's member
+// list, optionally setting a default namespace for some of its members.
+type GroupAddOp struct {
+	Name       string
+	Contexts   []string
+	Namespaces map[string]string // ctx -> default namespace, from "ctx=ns" members
+}
+
+func (op GroupAddOp) Run(_, stderr io.Writer) error {
+	cfg, err := groups.Load(groups.DefaultPath())
+	if err != nil {
+		return errors.Wrap(err, "failed to load groups file")
+	}
+	cfg.Add(op.Name, op.Contexts, op.Namespaces)
+	if err := cfg.Save(); err != nil {
+		return errors.Wrap(err, "failed to save groups file")
+	}
+	return errors.Wrap(printer.Success(stderr, "Group \"%s\" now has %d context(s).",
+		printer.SuccessColor.Sprint(op.Name), len(op.Contexts)), "print error")
+}
+
+// parseGroupMembers splits --group-add's member arguments into an ordered
+// context list and an optional ctx->namespace map, accepting either bare
+// "CONTEXT" or "CONTEXT=NAMESPACE" tokens to set that member's default
+// namespace.
+func parseGroupMembers(args []string) ([]string, map[string]string, error) {
+	contexts := make([]string, 0, len(args))
+	var namespaces map[string]string
+	for _, a := range args {
+		ctx := a
+		if i := strings.Index(a, "="); i >= 0 {
+			var ns string
+			ctx, ns = a[:i], a[i+1:]
+			if ctx == "" || ns == "" {
+				return nil, nil, fmt.Errorf("invalid group member \"%s\", expected CONTEXT or CONTEXT=NAMESPACE", a)
+			}
+			if namespaces == nil {
+				namespaces = map[string]string{}
+			}
+			namespaces[ctx] = ns
+		}
+		contexts = append(contexts, ctx)
+	}
+	return contexts, namespaces, nil
+}
+
+// GroupRemoveOp indicates intention to delete a group.
+type GroupRemoveOp struct {
+	Name string
+}
+
+func (op GroupRemoveOp) Run(_, stderr io.Writer) error {
+	cfg, err := groups.Load(groups.DefaultPath())
+	if err != nil {
+		return errors.Wrap(err, "failed to load groups file")
+	}
+	cfg.Remove(op.Name)
+	if err := cfg.Save(); err != nil {
+		return errors.Wrap(err, "failed to save groups file")
+	}
+	return errors.Wrap(printer.Success(stderr, "Group \"%s\" removed.", printer.SuccessColor.Sprint(op.Name)), "print error")
+}
+
+// resolveGroupTarget returns the member context a group name should switch
+// to (the last-used member if one is recorded and still a member,
+// otherwise the group's first context), and whether name is a group at all.
+func resolveGroupTarget(name string) (string, bool, error) {
+	cfg, err := groups.Load(groups.DefaultPath())
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to load groups file")
+	}
+	g, ok := cfg.Get(name)
+	if !ok || len(g.Contexts) == 0 {
+		return "", false, nil
+	}
+
+	target := g.Contexts[0]
+	if statePath, err := groupStateFile(); err == nil {
+		if last, err := readGroupState(statePath, name); err == nil && last != "" {
+			for _, c := range g.Contexts {
+				if c == last {
+					target = last
+					break
+				}
+			}
+		}
+	}
+	return target, true, nil
+}
+
+// rememberGroupSwitch records ctx as the last-used member of group, so the
+// next plain switch to the group name lands back on it.
+func rememberGroupSwitch(group, ctx string) error {
+	statePath, err := groupStateFile()
+	if err != nil {
+		return errors.Wrap(err, "failed to determine group state file")
+	}
+	return errors.Wrap(writeGroupState(statePath, group, ctx), "failed to save last-used group member")
+}
+
+// groupDefaultNamespace returns the default namespace configured for ctx
+// within the group named name, or "" if name isn't a group or has no
+// default namespace set for ctx.
+func groupDefaultNamespace(name, ctx string) string {
+	cfg, err := groups.Load(groups.DefaultPath())
+	if err != nil {
+		return ""
+	}
+	g, ok := cfg.Get(name)
+	if !ok {
+		return ""
+	}
+	return g.Namespaces[ctx]
+}
+
+// groupBadges returns a human-readable suffix listing the groups ctx
+// belongs to (e.g. " (prod-eu, staging)"), or "" if it belongs to none.
+// ListOp should append this next to each context it renders.
+func groupBadges(ctx string) string {
+	cfg, err := groups.Load(groups.DefaultPath())
+	if err != nil {
+		return ""
+	}
+	members := cfg.MembersOf(ctx)
+	if len(members) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(members, ", "))
+}
+
+// groupStateFile returns the path of the small state file that tracks each
+// group's last-used member context, kept alongside kubectxPrevCtxFile.
+func groupStateFile() (string, error) {
+	prevCtxFile, err := kubectxPrevCtxFile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(prevCtxFile), "kubectx-groups-state"), nil
+}
+
+// readGroupState returns the last-used context recorded for group in the
+// state file at path, or "" if none is recorded.
+func readGroupState(path, group string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) == 2 && parts[0] == group {
+			return parts[1], nil
+		}
+	}
+	return "", nil
+}
+
+// writeGroupState records ctx as the last-used context for group in the
+// state file at path, preserving entries for other groups.
+func writeGroupState(path, group, ctx string) error {
+	state := map[string]string{}
+	if b, err := ioutil.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(b), "\n") {
+			parts := strings.SplitN(line, "\t", 2)
+			if len(parts) == 2 {
+				state[parts[0]] = parts[1]
+			}
+		}
+	}
+	state[group] = ctx
+
+	var sb strings.Builder
+	for g, c := range state {
+		fmt.Fprintf(&sb, "%s\t%s\n", g, c)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(sb.String()), 0644)
+}
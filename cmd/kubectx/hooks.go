@@ -0,0 +1,144 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/ahmetb/kubectx/internal/cmdutil"
+	"github.com/ahmetb/kubectx/internal/printer"
+)
+
+// runPreSwitchHooks/runPostSwitchHooks are only invoked from SwitchOp.Run
+// (see switch.go); deleting or renaming a context does not run any hooks.
+
+var kubectxHooksDir = filepath.Join(cmdutil.HomeDir(), ".kube", "kubectx.d")
+
+// hookEnv describes the context switch a hook is being invoked for.
+type hookEnv struct {
+	Prev       string
+	New        string
+	Namespace  string
+	Kubeconfig string
+}
+
+func (e hookEnv) environ() []string {
+	return append(os.Environ(),
+		"KUBECTX_PREV="+e.Prev,
+		"KUBECTX_NEW="+e.New,
+		"KUBECTX_NAMESPACE="+e.Namespace,
+		"KUBECTX_KUBECONFIG="+e.Kubeconfig,
+	)
+}
+
+// hooksEnabled reports whether the hook subsystem should run, honoring the
+// --no-hooks flag and the KUBECTX_DISABLE_HOOKS escape hatch.
+func hooksEnabled(noHooksFlag bool) bool {
+	if noHooksFlag {
+		return false
+	}
+	return os.Getenv("KUBECTX_DISABLE_HOOKS") == ""
+}
+
+// runPreSwitchHooks runs every executable under kubectx.d/pre-switch, in
+// name order. The first one to exit non-zero aborts the switch, so the
+// kubeconfig is left untouched.
+func runPreSwitchHooks(noHooks bool, env hookEnv, stdout, stderr io.Writer) error {
+	if !hooksEnabled(noHooks) {
+		return nil
+	}
+	return runHooks(filepath.Join(kubectxHooksDir, "pre-switch"), env, stdout, stderr, true)
+}
+
+// runPostSwitchHooks runs every executable under kubectx.d/post-switch,
+// after the switch has already been saved. Failures are only surfaced as
+// warnings; they never undo or fail the switch itself.
+func runPostSwitchHooks(noHooks bool, env hookEnv, stdout, stderr io.Writer) {
+	if !hooksEnabled(noHooks) {
+		return
+	}
+	_ = runHooks(filepath.Join(kubectxHooksDir, "post-switch"), env, stdout, stderr, false)
+}
+
+func runHooks(dir string, env hookEnv, stdout, stderr io.Writer, abortOnError bool) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to read hooks dir %q", dir)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil || info.Mode()&0111 == 0 {
+			continue // skip unreadable or non-executable entries
+		}
+
+		cmd := exec.Command(path)
+		cmd.Env = env.environ()
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			wrapped := errors.Wrapf(err, "hook \"%s\" failed", path)
+			if abortOnError {
+				return wrapped
+			}
+			_ = printer.Warning(stderr, "%s", wrapped)
+		}
+	}
+	return nil
+}
+
+// kubeconfigPath returns the kubeconfig path hooks should be told about via
+// KUBECTX_KUBECONFIG.
+func kubeconfigPath() string {
+	if v := os.Getenv("KUBECONFIG"); v != "" {
+		return v
+	}
+	return clientcmd.RecommendedHomeFile
+}
+
+// stripNoHooksFlag removes a "--no-hooks" flag from argv, wherever it
+// appears, and reports whether it was present.
+func stripNoHooksFlag(argv []string) ([]string, bool) {
+	out := argv[:0:0]
+	var found bool
+	for _, a := range argv {
+		if a == "--no-hooks" {
+			found = true
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, found
+}
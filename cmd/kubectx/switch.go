@@ -40,11 +40,21 @@ import (
 type SwitchOp struct {
 	Target    string // '-' for back and forth, or NAME
 	Namespace string // namespace to switch to after context switch (optional)
+	NoHooks   bool   // --no-hooks: bypass pre/post-switch hooks
 }
 
-func (op SwitchOp) Run(_, stderr io.Writer) error {
+func (op SwitchOp) Run(stdout, stderr io.Writer) error {
+	prevCtx, newTarget, err := resolveSwitchTarget(op.Target)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve switch target")
+	}
+
+	env := hookEnv{Prev: prevCtx, New: newTarget, Namespace: op.Namespace, Kubeconfig: kubeconfigPath()}
+	if err := runPreSwitchHooks(op.NoHooks, env, stdout, stderr); err != nil {
+		return errors.Wrap(err, "pre-switch hook aborted switch")
+	}
+
 	var newCtx string
-	var err error
 	if op.Target == "-" {
 		newCtx, err = swapContext()
 	} else {
@@ -53,16 +63,23 @@ func (op SwitchOp) Run(_, stderr io.Writer) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to switch context")
 	}
+	env.New = newCtx
+	runPostSwitchHooks(op.NoHooks, env, stdout, stderr)
 
-	// Switch namespace if specified
-	if op.Namespace != "" {
+	// Switch namespace if specified, falling back to the target group's
+	// default namespace for the resolved context, if any.
+	namespace := op.Namespace
+	if namespace == "" {
+		namespace = groupDefaultNamespace(op.Target, newCtx)
+	}
+	if namespace != "" {
 		kc := new(kubeconfig.Kubeconfig).WithLoader(kubeconfig.DefaultLoader)
 		defer kc.Close()
 		if err := kc.Parse(); err != nil {
 			return errors.Wrap(err, "kubeconfig error")
 		}
 
-		toNS, err := switchNamespace(kc, newCtx, op.Namespace, false)
+		toNS, err := switchNamespace(kc, newCtx, namespace, false)
 		if err != nil {
 			return errors.Wrap(err, "failed to switch namespace")
 		}
@@ -75,6 +92,46 @@ func (op SwitchOp) Run(_, stderr io.Writer) error {
 	return errors.Wrap(err, "print error")
 }
 
+// resolveSwitchTarget returns the kubeconfig's current context, and the
+// context name target will resolve to without mutating anything, so
+// pre-switch hooks can be told the real KUBECTX_PREV/KUBECTX_NEW before the
+// switch is committed. It uses the exact same existence-checked precedence
+// as switchContext (a literal context always wins over a group of the same
+// name), so the prediction can never disagree with the actual switch.
+func resolveSwitchTarget(target string) (prev, resolved string, err error) {
+	kc := new(kubeconfig.Kubeconfig).WithLoader(kubeconfig.DefaultLoader)
+	defer kc.Close()
+	if err := kc.Parse(); err != nil {
+		return "", "", errors.Wrap(err, "kubeconfig error")
+	}
+	prev = kc.GetCurrentContext()
+
+	if target == "-" {
+		prevCtxFile, err := kubectxPrevCtxFile()
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to determine state file")
+		}
+		last, err := readLastContext(prevCtxFile)
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to read previous context file")
+		}
+		if last == "" {
+			return "", "", errors.New("no previous context found")
+		}
+		return prev, last, nil
+	}
+
+	if kc.ContextExists(target) {
+		return prev, target, nil
+	}
+	if g, ok, err := resolveGroupTarget(target); err != nil {
+		return "", "", err
+	} else if ok {
+		return prev, g, nil
+	}
+	return prev, target, nil
+}
+
 // switchContext switches to specified context name.
 func switchContext(name string) (string, error) {
 	prevCtxFile, err := kubectxPrevCtxFile()
@@ -89,22 +146,37 @@ func switchContext(name string) (string, error) {
 	}
 
 	prev := kc.GetCurrentContext()
+
+	resolved := name
 	if !kc.ContextExists(name) {
+		if target, ok, err := resolveGroupTarget(name); err != nil {
+			return "", err
+		} else if ok {
+			resolved = target
+		}
+	}
+
+	if !kc.ContextExists(resolved) {
 		return "", errors.Errorf("no context exists with the name: \"%s\"", name)
 	}
-	if err := kc.ModifyCurrentContext(name); err != nil {
+	if err := kc.ModifyCurrentContext(resolved); err != nil {
 		return "", err
 	}
 	if err := kc.Save(); err != nil {
 		return "", errors.Wrap(err, "failed to save kubeconfig")
 	}
 
-	if prev != name {
+	if prev != resolved {
 		if err := writeLastContext(prevCtxFile, prev); err != nil {
 			return "", errors.Wrap(err, "failed to save previous context name")
 		}
 	}
-	return name, nil
+	if resolved != name {
+		if err := rememberGroupSwitch(name, resolved); err != nil {
+			return "", err
+		}
+	}
+	return resolved, nil
 }
 
 // swapContext switches to previously switch context.
@@ -0,0 +1,54 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// UnsupportedOp indicates an unsupported flag.
+type UnsupportedOp struct{ Err error }
+
+func (op UnsupportedOp) Run(_, _ io.Writer) error {
+	return op.Err
+}
+
+// parseArgs looks at flags (excl. executable name) and decides which
+// operation should be taken. "--no-verify" and "--refresh" are accepted
+// anywhere in argv and stripped before the remaining positional argument
+// (the target namespace, or '-') is interpreted.
+func parseArgs(argv []string) Op {
+	var force, refresh bool
+	var remaining []string
+	for _, a := range argv {
+		switch a {
+		case "--no-verify":
+			force = true
+		case "--refresh":
+			refresh = true
+		default:
+			remaining = append(remaining, a)
+		}
+	}
+
+	if len(remaining) == 0 {
+		return ListOp{}
+	}
+	if len(remaining) > 1 {
+		return UnsupportedOp{Err: fmt.Errorf("too many arguments")}
+	}
+	return SwitchOp{Target: remaining[0], Force: force, Refresh: refresh}
+}
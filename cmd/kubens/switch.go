@@ -0,0 +1,264 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/ahmetb/kubectx/internal/cmdutil"
+	"github.com/ahmetb/kubectx/internal/kubeconfig"
+	"github.com/ahmetb/kubectx/internal/nscache"
+	"github.com/ahmetb/kubectx/internal/printer"
+)
+
+const defaultCacheTTL = 10 * time.Minute
+
+var nsCacheDir = filepath.Join(cmdutil.HomeDir(), ".kube", "kubens", "cache")
+
+// SwitchOp indicates intention to switch the active namespace of the
+// current context.
+type SwitchOp struct {
+	Target  string // '-' for back and forth, or NAME
+	Force   bool   // --no-verify: skip live verification that the namespace exists
+	Refresh bool   // --refresh: force a live namespace list and repopulate the cache
+}
+
+func (op SwitchOp) Run(_, stderr io.Writer) error {
+	kc := new(kubeconfig.Kubeconfig).WithLoader(kubeconfig.DefaultLoader)
+	defer kc.Close()
+	if err := kc.Parse(); err != nil {
+		return errors.Wrap(err, "kubeconfig error")
+	}
+	ctx := kc.GetCurrentContext()
+
+	var newNS string
+	var err error
+	if op.Target == "-" {
+		newNS, err = swapNamespace(kc, ctx)
+	} else {
+		newNS, err = switchNamespace(kc, ctx, op.Target, op.Force, op.Refresh)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to switch namespace")
+	}
+
+	err = printer.Success(stderr, "Active namespace is \"%s\".", printer.SuccessColor.Sprint(newNS))
+	return errors.Wrap(err, "print error")
+}
+
+// swapNamespace switches to the previously active namespace of ctx.
+func swapNamespace(kc *kubeconfig.Kubeconfig, ctx string) (string, error) {
+	f := NewNSFile(ctx)
+	prev, err := f.Load()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read previous namespace file")
+	}
+	if prev == "" {
+		return "", errors.New("no previous namespace found")
+	}
+	return switchNamespace(kc, ctx, prev, false, false)
+}
+
+// switchNamespace switches ctx's active namespace to ns. Unless force is
+// set, it first verifies ns exists, preferring the on-disk cache so the
+// switch keeps working against air-gapped or momentarily unreachable
+// clusters; refresh bypasses the cache and repopulates it from a live call.
+func switchNamespace(kc *kubeconfig.Kubeconfig, ctx, ns string, force, refresh bool) (string, error) {
+	curNS, err := kc.NamespaceOfContext(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get current namespace")
+	}
+
+	f := NewNSFile(ctx)
+
+	if !force {
+		ok, err := namespaceExists(kc, ctx, ns, refresh)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to query if namespace exists (is cluster accessible?)")
+		}
+		if !ok {
+			return "", errors.Errorf("no namespace exists with name \"%s\"", ns)
+		}
+	}
+
+	if err := kc.SetNamespace(ctx, ns); err != nil {
+		return "", errors.Wrapf(err, "failed to change to namespace \"%s\"", ns)
+	}
+	if err := kc.Save(); err != nil {
+		return "", errors.Wrap(err, "failed to save kubeconfig file")
+	}
+	if curNS != ns {
+		if err := f.Save(curNS); err != nil {
+			return "", errors.Wrap(err, "failed to save the previous namespace to file")
+		}
+	}
+	return ns, nil
+}
+
+// cacheTTL returns the configured namespace cache TTL, defaulting to
+// defaultCacheTTL if KUBENS_CACHE_TTL is unset or invalid.
+func cacheTTL() time.Duration {
+	v := os.Getenv("KUBENS_CACHE_TTL")
+	if v == "" {
+		return defaultCacheTTL
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return defaultCacheTTL
+	}
+	return d
+}
+
+// namespaceExists reports whether ns exists in ctx's cluster. Unless
+// refresh is set, a fresh cache entry is consulted first; only a cache
+// miss or a forced refresh reaches the live API, and a successful live
+// call always repopulates the cache for next time.
+func namespaceExists(kc *kubeconfig.Kubeconfig, ctx, ns string, refresh bool) (bool, error) {
+	// for tests
+	if os.Getenv("_MOCK_NAMESPACES") != "" {
+		return ns == "ns1" || ns == "ns2", nil
+	}
+
+	cache := nscache.New(nsCacheDir, cacheTTL())
+
+	if !refresh {
+		if namespaces, fresh := cache.Load(ctx); fresh {
+			return contains(namespaces, ns), nil
+		}
+	}
+
+	namespaces, err := listNamespaces(kc)
+	if err != nil {
+		if ok, known := cache.Contains(ctx, ns); known {
+			return ok, nil
+		}
+		return false, err
+	}
+
+	exists := contains(namespaces, ns)
+	if !exists {
+		// the live API is authoritative here: explicitly drop any stale
+		// "exists" cache entry rather than relying on the Save below to
+		// overwrite it.
+		if err := cache.Invalidate(ctx); err != nil {
+			return false, errors.Wrap(err, "failed to invalidate namespace cache")
+		}
+	}
+	if err := cache.Save(ctx, namespaces); err != nil {
+		return false, errors.Wrap(err, "failed to update namespace cache")
+	}
+	return exists, nil
+}
+
+func listNamespaces(kc *kubeconfig.Kubeconfig) ([]string, error) {
+	clientset, err := newKubernetesClientSet(kc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize k8s REST client")
+	}
+	list, err := clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list namespaces from k8s API")
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, n := range list.Items {
+		names = append(names, n.Name)
+	}
+	return names, nil
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newKubernetesClientSet(kc *kubeconfig.Kubeconfig) (*kubernetes.Clientset, error) {
+	b, err := kc.Bytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert in-memory kubeconfig to yaml")
+	}
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize config")
+	}
+	return kubernetes.NewForConfig(cfg)
+}
+
+// NSFile manages previous-namespace state files for contexts.
+type NSFile struct {
+	dir string
+	ctx string
+}
+
+var nsFileDefaultDir = filepath.Join(cmdutil.HomeDir(), ".kube", "kubens")
+
+func NewNSFile(ctx string) NSFile {
+	return NSFile{dir: nsFileDefaultDir, ctx: ctx}
+}
+
+func (f NSFile) path() string {
+	fn := f.ctx
+	if isWindows() {
+		// bug 230: eks clusters contain ':' in ctx name, not a valid file name for win32
+		fn = strings.ReplaceAll(fn, ":", "__")
+	}
+	return filepath.Join(f.dir, fn)
+}
+
+// Load reads the previous namespace setting, or returns empty if not exists.
+func (f NSFile) Load() (string, error) {
+	b, err := ioutil.ReadFile(f.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(bytes.TrimSpace(b)), nil
+}
+
+// Save stores the previous namespace information in the file.
+func (f NSFile) Save(value string) error {
+	d := filepath.Dir(f.path())
+	if err := os.MkdirAll(d, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.path(), []byte(value), 0644)
+}
+
+// isWindows determines if the process is running on windows OS.
+func isWindows() bool {
+	if os.Getenv("_FORCE_GOOS") == "windows" { // for testing
+		return true
+	}
+	return runtime.GOOS == "windows"
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package groups reads and writes the kubectx groups file, a sidecar YAML
+// config (by default ~/.kube/kubectx.groups.yaml) that lets users bookmark
+// a name like "prod" to an ordered list of kubeconfig contexts.
+package groups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ahmetb/kubectx/internal/cmdutil"
+)
+
+// Group is an ordered, named collection of context names, plus an optional
+// default namespace per member context.
+type Group struct {
+	Contexts   []string          `yaml:"contexts"`
+	Namespaces map[string]string `yaml:"namespaces,omitempty"`
+}
+
+// Config is the in-memory, mutable representation of the groups file.
+type Config struct {
+	Groups map[string]Group
+
+	path string
+}
+
+// DefaultPath returns the default location of the groups file.
+func DefaultPath() string {
+	return filepath.Join(cmdutil.HomeDir(), ".kube", "kubectx.groups.yaml")
+}
+
+// Load reads the groups file at path. A missing file is not an error; it
+// yields an empty Config ready to be populated and saved.
+func Load(path string) (*Config, error) {
+	c := &Config{Groups: map[string]Group{}, path: path}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, errors.Wrap(err, "failed to read groups file")
+	}
+	if err := yaml.Unmarshal(b, &c.Groups); err != nil {
+		return nil, errors.Wrap(err, "failed to parse groups file")
+	}
+	if c.Groups == nil {
+		c.Groups = map[string]Group{}
+	}
+	return c, nil
+}
+
+// Save writes the config back to its source path.
+func (c *Config) Save() error {
+	b, err := yaml.Marshal(c.Groups)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal groups file")
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create groups file dir")
+	}
+	return ioutil.WriteFile(c.path, b, 0644)
+}
+
+// Get returns the group named name, if one exists.
+func (c *Config) Get(name string) (Group, bool) {
+	g, ok := c.Groups[name]
+	return g, ok
+}
+
+// Add creates or replaces the group named name with the given ordered
+// context list. namespaces optionally sets the default namespace for any of
+// those members explicitly (e.g. parsed from "ctx=ns" CLI syntax); for a
+// member not present in namespaces, any default namespace it previously had
+// is preserved. Members that were dropped lose their entry either way.
+func (c *Config) Add(name string, contexts []string, namespaces map[string]string) {
+	existing := c.Groups[name]
+
+	merged := map[string]string{}
+	for _, ctx := range contexts {
+		if ns, ok := namespaces[ctx]; ok {
+			merged[ctx] = ns
+			continue
+		}
+		if ns, ok := existing.Namespaces[ctx]; ok {
+			merged[ctx] = ns
+		}
+	}
+	if len(merged) == 0 {
+		merged = nil
+	}
+	c.Groups[name] = Group{Contexts: contexts, Namespaces: merged}
+}
+
+// Remove deletes the group named name, if it exists.
+func (c *Config) Remove(name string) {
+	delete(c.Groups, name)
+}
+
+// MembersOf returns the names of every group that contains ctx, sorted for
+// stable display, e.g. as a badge next to the context in `kubectx` output.
+func (c *Config) MembersOf(ctx string) []string {
+	var names []string
+	for name, g := range c.Groups {
+		for _, m := range g.Contexts {
+			if m == ctx {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
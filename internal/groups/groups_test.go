@@ -0,0 +1,152 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groups
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(c.Groups) != 0 {
+		t.Fatalf("got %d groups, want 0 for a missing file", len(c.Groups))
+	}
+}
+
+func TestAddGetRemove(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "groups.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	c.Add("prod", []string{"prod-us", "prod-eu"}, nil)
+	g, ok := c.Get("prod")
+	if !ok {
+		t.Fatal("want group \"prod\" to exist after Add")
+	}
+	if !reflect.DeepEqual(g.Contexts, []string{"prod-us", "prod-eu"}) {
+		t.Fatalf("got contexts %v, want [prod-us prod-eu]", g.Contexts)
+	}
+
+	c.Remove("prod")
+	if _, ok := c.Get("prod"); ok {
+		t.Fatal("want group \"prod\" to be gone after Remove")
+	}
+}
+
+func TestAddPreservesNamespacesForRemainingMembers(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "groups.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	c.Add("prod", []string{"prod-us", "prod-eu"}, map[string]string{"prod-us": "default"})
+
+	// re-adding without "prod-us" in namespaces should preserve its existing
+	// default namespace, since it's still a member; prod-eu never had one.
+	c.Add("prod", []string{"prod-us", "prod-eu"}, nil)
+	g, _ := c.Get("prod")
+	if g.Namespaces["prod-us"] != "default" {
+		t.Fatalf("got Namespaces[prod-us]=%q, want \"default\" to be preserved", g.Namespaces["prod-us"])
+	}
+	if _, ok := g.Namespaces["prod-eu"]; ok {
+		t.Fatalf("got Namespaces[prod-eu] set, want it absent")
+	}
+}
+
+func TestAddDropsNamespaceForRemovedMember(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "groups.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	c.Add("prod", []string{"prod-us", "prod-eu"}, map[string]string{"prod-us": "default"})
+	c.Add("prod", []string{"prod-eu"}, nil)
+
+	g, _ := c.Get("prod")
+	if len(g.Namespaces) != 0 {
+		t.Fatalf("got Namespaces %v, want empty once prod-us is dropped from the group", g.Namespaces)
+	}
+}
+
+func TestAddOverridesExplicitNamespace(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "groups.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	c.Add("prod", []string{"prod-us"}, map[string]string{"prod-us": "default"})
+	c.Add("prod", []string{"prod-us"}, map[string]string{"prod-us": "staging"})
+
+	g, _ := c.Get("prod")
+	if g.Namespaces["prod-us"] != "staging" {
+		t.Fatalf("got Namespaces[prod-us]=%q, want \"staging\" to override the prior default", g.Namespaces["prod-us"])
+	}
+}
+
+func TestMembersOf(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), "groups.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	c.Add("prod", []string{"prod-us", "prod-eu"}, nil)
+	c.Add("staging", []string{"prod-us"}, nil)
+
+	members := c.MembersOf("prod-us")
+	if !reflect.DeepEqual(members, []string{"prod", "staging"}) {
+		t.Fatalf("got members %v, want [prod staging] (sorted)", members)
+	}
+
+	if members := c.MembersOf("prod-eu"); !reflect.DeepEqual(members, []string{"prod"}) {
+		t.Fatalf("got members %v, want [prod]", members)
+	}
+
+	if members := c.MembersOf("dev"); members != nil {
+		t.Fatalf("got members %v, want nil for a context in no group", members)
+	}
+}
+
+func TestSaveAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "groups.yaml")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	c.Add("prod", []string{"prod-us", "prod-eu"}, map[string]string{"prod-us": "default"})
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load (reload): %v", err)
+	}
+	g, ok := reloaded.Get("prod")
+	if !ok {
+		t.Fatal("want group \"prod\" to survive a Save/Load round-trip")
+	}
+	if !reflect.DeepEqual(g.Contexts, []string{"prod-us", "prod-eu"}) {
+		t.Fatalf("got contexts %v, want [prod-us prod-eu]", g.Contexts)
+	}
+	if g.Namespaces["prod-us"] != "default" {
+		t.Fatalf("got Namespaces[prod-us]=%q, want \"default\" to survive the round-trip", g.Namespaces["prod-us"])
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nscache provides a small on-disk cache of per-context namespace
+// lists, so namespace-exists checks can be served without reaching the
+// cluster API every time.
+package nscache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// entry is the on-disk representation of a cached namespace list.
+type entry struct {
+	Namespaces []string  `json:"namespaces"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+}
+
+// Cache manages per-context namespace list caches under a directory such as
+// ~/.kube/kubens/cache.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// New returns a Cache rooted at dir, treating entries older than ttl as
+// stale.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+func (c *Cache) path(ctx string) string {
+	fn := ctx
+	if runtime.GOOS == "windows" {
+		// bug 230: eks clusters contain ':' in ctx name, not a valid file name for win32
+		fn = strings.ReplaceAll(fn, ":", "__")
+	}
+	return filepath.Join(c.dir, fn)
+}
+
+// Load returns the cached namespace list for ctx and whether it is still
+// fresh. A missing or corrupt cache file is treated as a cache miss rather
+// than an error.
+func (c *Cache) Load(ctx string) (namespaces []string, fresh bool) {
+	b, err := ioutil.ReadFile(c.path(ctx))
+	if err != nil {
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, false
+	}
+	return e.Namespaces, time.Since(e.FetchedAt) < c.ttl
+}
+
+// Contains reports whether ns is present in the cached list for ctx
+// regardless of freshness, and whether any cache entry was found at all.
+func (c *Cache) Contains(ctx, ns string) (ok, known bool) {
+	namespaces, _ := c.Load(ctx)
+	if namespaces == nil {
+		return false, false
+	}
+	for _, n := range namespaces {
+		if n == ns {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// Save persists namespaces as ctx's cache entry, stamped with the current
+// time. The file is written with 0600 permissions since a cluster's
+// namespace inventory can be sensitive.
+func (c *Cache) Save(ctx string, namespaces []string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create namespace cache dir")
+	}
+	b, err := json.Marshal(entry{Namespaces: namespaces, FetchedAt: time.Now()})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal namespace cache entry")
+	}
+	return ioutil.WriteFile(c.path(ctx), b, 0600)
+}
+
+// Invalidate removes the cache entry for ctx, e.g. after a live API call
+// reports that a previously-cached namespace no longer exists.
+func (c *Cache) Invalidate(ctx string) error {
+	err := os.Remove(c.path(ctx))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nscache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissing(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+	namespaces, fresh := c.Load("ctx1")
+	if namespaces != nil || fresh {
+		t.Fatalf("got (%v, %v), want (nil, false) for missing entry", namespaces, fresh)
+	}
+}
+
+func TestLoadFresh(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+	if err := c.Save("ctx1", []string{"ns1", "ns2"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	namespaces, fresh := c.Load("ctx1")
+	if !fresh {
+		t.Fatal("want fresh entry right after Save")
+	}
+	if len(namespaces) != 2 || namespaces[0] != "ns1" || namespaces[1] != "ns2" {
+		t.Fatalf("got namespaces %v, want [ns1 ns2]", namespaces)
+	}
+}
+
+func TestLoadStale(t *testing.T) {
+	c := New(t.TempDir(), time.Millisecond)
+	if err := c.Save("ctx1", []string{"ns1"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	namespaces, fresh := c.Load("ctx1")
+	if fresh {
+		t.Fatal("want stale entry after ttl elapses")
+	}
+	if len(namespaces) != 1 || namespaces[0] != "ns1" {
+		t.Fatalf("got namespaces %v, want [ns1] (stale entries still return their data)", namespaces)
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, time.Hour)
+	if err := ioutil.WriteFile(filepath.Join(dir, "ctx1"), []byte("not json"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	namespaces, fresh := c.Load("ctx1")
+	if namespaces != nil || fresh {
+		t.Fatalf("got (%v, %v), want (nil, false) for corrupt entry", namespaces, fresh)
+	}
+}
+
+func TestContains(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+
+	if ok, known := c.Contains("ctx1", "ns1"); ok || known {
+		t.Fatalf("got (%v, %v), want (false, false) before any Save", ok, known)
+	}
+
+	if err := c.Save("ctx1", []string{"ns1", "ns2"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if ok, known := c.Contains("ctx1", "ns1"); !ok || !known {
+		t.Fatalf("got (%v, %v), want (true, true) for cached member", ok, known)
+	}
+	if ok, known := c.Contains("ctx1", "ns3"); ok || !known {
+		t.Fatalf("got (%v, %v), want (false, true) for a known context missing that namespace", ok, known)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, time.Hour)
+	if err := c.Save("ctx1", []string{"ns1"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if err := c.Invalidate("ctx1"); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+	if namespaces, fresh := c.Load("ctx1"); namespaces != nil || fresh {
+		t.Fatalf("got (%v, %v) after Invalidate, want (nil, false)", namespaces, fresh)
+	}
+
+	// invalidating an already-missing entry is not an error
+	if err := c.Invalidate("ctx1"); err != nil {
+		t.Fatalf("Invalidate on missing entry: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ctx1")); !os.IsNotExist(err) {
+		t.Fatalf("cache file still present after Invalidate: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package printer centralizes the colored terminal output used by the
+// kubectx/kubens binaries, so coloring stays consistent instead of each op
+// picking its own fatih/color instances.
+package printer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// SuccessColor is used to highlight the context/namespace name in a
+// successful switch message.
+var SuccessColor = color.New(color.FgGreen, color.Bold)
+
+// WarningColor is used to highlight warning labels, e.g. for a failed
+// post-switch hook.
+var WarningColor = color.New(color.FgYellow, color.Bold)
+
+// fanOutColors cycles through a small palette used to tell per-context
+// output apart, e.g. in `kubectx --each`.
+var fanOutColors = []*color.Color{
+	color.New(color.FgCyan),
+	color.New(color.FgMagenta),
+	color.New(color.FgYellow),
+	color.New(color.FgGreen),
+	color.New(color.FgBlue),
+}
+
+// FanOutColor returns the i'th fan-out prefix color, cycling through a
+// fixed palette so callers don't need their own color.Color instances.
+func FanOutColor(i int) *color.Color {
+	return fanOutColors[i%len(fanOutColors)]
+}
+
+// Success prints a success message to w.
+func Success(w io.Writer, format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(w, format+"\n", a...)
+	return err
+}
+
+// Warning prints a warning message to w, prefixed with a colored label.
+func Warning(w io.Writer, format string, a ...interface{}) error {
+	_, err := fmt.Fprintf(w, "%s %s\n", WarningColor.Sprint("WARNING:"), fmt.Sprintf(format, a...))
+	return err
+}